@@ -2,9 +2,11 @@ package redis
 
 import (
 	"runtime"
+	"sync"
 	"time"
 
 	"github.com/kataras/golog"
+	"github.com/kataras/iris/context"
 	"github.com/kataras/iris/sessions"
 	"github.com/ybtq/iris-redis/service"
 )
@@ -12,6 +14,13 @@ import (
 // Database the redis back-end session database for the sessions.
 type Database struct {
 	redis *service.Service
+
+	// buffered holds the in-memory, not-yet-flushed Store of a sid when
+	// Config.Buffered is true. A session's mutations are buffered here
+	// instead of round-tripping to redis on every Set/Delete/Clear call, and
+	// written back once through Flush.
+	mu       sync.Mutex
+	buffered map[string]*Store
 }
 
 var _ sessions.Database = (*Database)(nil)
@@ -39,11 +48,16 @@ func (db *Database) Config() *service.Config {
 func (db *Database) Acquire(sid string, expires time.Duration) sessions.LifeTime {
 	seconds, hasExpiration, found := db.redis.TTL(sid)
 	if !found {
-		// not found, create an entry with ttl and return an empty lifetime, session manager will do its job.
-		var emptyData []byte
-		if err := db.redis.Set(sid, emptyData, int64(expires.Seconds())); err != nil {
-			golog.Error(err)
+		if !db.redis.Config.HashMode {
+			// not found, create an entry with ttl and return an empty lifetime, session manager will do its job.
+			var emptyData []byte
+			if err := db.redis.Set(sid, emptyData, int64(expires.Seconds())); err != nil {
+				golog.Error(err)
+			}
 		}
+		// in HashMode there's nothing to seed here: the key doesn't exist yet
+		// and the first hSet call creates it via HSET, which would fail with
+		// WRONGTYPE against a string placeholder written by the branch above.
 
 		return sessions.LifeTime{} // session manager will handle the rest.
 	}
@@ -64,15 +78,64 @@ func (db *Database) OnUpdateExpiration(sid string, newExpires time.Duration) err
 // Set sets a key value of a specific session.
 // Ignore the "immutable".
 func (db *Database) Set(sid string, lifetime sessions.LifeTime, key string, value interface{}, immutable bool) {
+	if db.redis.Config.HashMode {
+		db.hSet(sid, lifetime, key, value)
+		return
+	}
+
+	if db.redis.Config.Buffered {
+		db.bufferedStoreFor(sid).Set(key, value)
+		return
+	}
+
+	seconds := int64(lifetime.DurationUntilExpiration().Seconds())
 	store := NewStore()
 	db.get(sid, store)
 	store.values[key] = value
 	golog.Debug("Set", sid, lifetime, key, value, store.values)
-	db.set(sid, int64(lifetime.DurationUntilExpiration().Seconds()), store)
+	db.set(sid, seconds, store)
+}
+
+// bufferedStoreFor returns the in-memory store buffered for sid, loading it
+// from redis (a single GET) the first time it's requested.
+func (db *Database) bufferedStoreFor(sid string) *Store {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	if db.buffered == nil {
+		db.buffered = make(map[string]*Store)
+	}
+
+	store, ok := db.buffered[sid]
+	if !ok {
+		store = NewStore()
+		db.get(sid, store)
+		db.buffered[sid] = store
+	}
+	return store
+}
+
+// hSet is the HashMode counterpart of Set, it stores the key as its own hash
+// field (HSET) instead of re-writing the whole session blob.
+func (db *Database) hSet(sid string, lifetime sessions.LifeTime, key string, value interface{}) {
+	valueBytes, err := service.MarshalValue(db.redis.Config.Serializer, value)
+	if err != nil {
+		golog.Error(err)
+		return
+	}
+
+	if err = db.redis.HSet(sid, key, valueBytes); err != nil {
+		golog.Error(err)
+		return
+	}
+
+	if err = db.redis.UpdateTTL(sid, int64(lifetime.DurationUntilExpiration().Seconds())); err != nil {
+		golog.Error(err)
+	}
 }
 
 func (db *Database) set(sid string, secondsLifetime int64, store *Store) {
-	valueBytes, err := store.Serialize()
+	valueBytes, err := store.Serialize(db.redis.Config.Serializer)
 	if err != nil {
 		golog.Error(err)
 		return
@@ -84,6 +147,25 @@ func (db *Database) set(sid string, secondsLifetime int64, store *Store) {
 
 // Get retrieves a session value based on the key.
 func (db *Database) Get(sid string, key string) (value interface{}) {
+	if db.redis.Config.HashMode {
+		data, err := db.redis.HGet(sid, key)
+		if err != nil {
+			// not found.
+			return nil
+		}
+
+		value, err = service.UnmarshalValue(db.redis.Config.Serializer, data.([]byte))
+		if err != nil {
+			golog.Error(err)
+			return nil
+		}
+		return value
+	}
+
+	if db.redis.Config.Buffered {
+		return db.bufferedStoreFor(sid).Get(key)
+	}
+
 	store := NewStore()
 	db.get(sid, store)
 	value = store.values[key]
@@ -97,7 +179,7 @@ func (db *Database) get(key string, store *Store) {
 		return
 	}
 
-	err = store.Deserialize(data.([]byte))
+	err = store.Deserialize(data.([]byte), db.redis.Config.Serializer)
 	if err != nil {
 		golog.Error(err)
 		return
@@ -112,8 +194,67 @@ func (db *Database) keys(sid string) []string {
 	return keys
 }
 
+// VisitAll loops through all the sessions stored in redis under the
+// configured Config.KeyPrefix, it's meant for admin/GC tooling that needs to
+// enumerate every session rather than a single sid. It uses SCAN under the
+// hood, never the blocking KEYS command. Config.KeyPrefix must be set to a
+// non-empty value or VisitAll logs service.ErrNoKeyPrefix and returns without
+// calling cb, since an unprefixed scan would otherwise walk every key in a
+// redis instance shared with other applications' data, not just sessions.
+func (db *Database) VisitAll(cb func(sid string)) {
+	sids, err := db.redis.Keys()
+	if err != nil {
+		golog.Error(err)
+		return
+	}
+
+	for _, sid := range sids {
+		cb(sid)
+	}
+}
+
 // Visit loops through all session keys and values.
 func (db *Database) Visit(sid string, cb func(key string, value interface{})) {
+	if db.redis.Config.HashMode {
+		var cursor uint64
+		for {
+			fieldsAndValues, next, err := db.redis.HScan(sid, cursor, 0)
+			if err != nil {
+				golog.Error(err)
+				return
+			}
+
+			for i := 0; i+1 < len(fieldsAndValues); i += 2 {
+				value, err := service.UnmarshalValue(db.redis.Config.Serializer, []byte(fieldsAndValues[i+1]))
+				if err != nil {
+					golog.Error(err)
+					continue
+				}
+				cb(fieldsAndValues[i], value)
+			}
+
+			cursor = next
+			if cursor == 0 {
+				return
+			}
+		}
+	}
+
+	if db.redis.Config.Buffered {
+		store := db.bufferedStoreFor(sid)
+		store.lock.RLock()
+		values := make(map[string]interface{}, len(store.values))
+		for key, value := range store.values {
+			values[key.(string)] = value
+		}
+		store.lock.RUnlock()
+
+		for key, value := range values {
+			cb(key, value)
+		}
+		return
+	}
+
 	store := NewStore()
 	db.get(sid, store)
 	for key, value := range store.values {
@@ -123,6 +264,22 @@ func (db *Database) Visit(sid string, cb func(key string, value interface{})) {
 
 // Len returns the length of the session's entries (keys).
 func (db *Database) Len(sid string) (n int) {
+	if db.redis.Config.HashMode {
+		length, err := db.redis.HLen(sid)
+		if err != nil {
+			golog.Error(err)
+			return 0
+		}
+		return int(length)
+	}
+
+	if db.redis.Config.Buffered {
+		store := db.bufferedStoreFor(sid)
+		store.lock.RLock()
+		defer store.lock.RUnlock()
+		return len(store.values)
+	}
+
 	store := NewStore()
 	db.get(sid, store)
 	return len(store.values)
@@ -130,6 +287,19 @@ func (db *Database) Len(sid string) (n int) {
 
 // Delete removes a session key value based on its key.
 func (db *Database) Delete(sid string, key string) (deleted bool) {
+	if db.redis.Config.HashMode {
+		deleted, err := db.redis.HDel(sid, key)
+		if err != nil {
+			golog.Error(err)
+			return false
+		}
+		return deleted
+	}
+
+	if db.redis.Config.Buffered {
+		return db.bufferedStoreFor(sid).DeleteExisting(key)
+	}
+
 	store := NewStore()
 	db.get(sid, store)
 	_, ok := store.values[key]
@@ -143,6 +313,20 @@ func (db *Database) Delete(sid string, key string) (deleted bool) {
 
 // Clear removes all session key values but it keeps the session entry.
 func (db *Database) Clear(sid string) {
+	if db.redis.Config.HashMode {
+		// there's no single-command way to empty a hash without deleting it,
+		// the next Set call will re-create it and re-apply the TTL.
+		if err := db.redis.Delete(sid); err != nil {
+			golog.Error(err)
+		}
+		return
+	}
+
+	if db.redis.Config.Buffered {
+		db.bufferedStoreFor(sid).Flush()
+		return
+	}
+
 	store := NewStore()
 	db.get(sid, store)
 	if len(store.values) > 0 {
@@ -151,12 +335,105 @@ func (db *Database) Clear(sid string) {
 	}
 }
 
+// RenameDatabase is an optional extension of sessions.Database for callers
+// that want to rotate a session id (e.g. on login, to defeat session
+// fixation attacks) without losing the session's data or TTL. iris's session
+// manager does not type-assert for this today, so application code wanting
+// id regeneration should call Rename directly against its *Database.
+type RenameDatabase interface {
+	Rename(oldSid, newSid string) error
+}
+
+var _ RenameDatabase = (*Database)(nil)
+
+// Rename migrates a session's data and remaining TTL from oldSid to newSid.
+// It's used to rotate the session id, e.g. on login, to defeat session
+// fixation attacks, without losing the session's values. It copies the data
+// across rather than issuing a redis RENAME, so it works safely even when
+// oldSid and newSid don't share a Cluster hash slot.
+func (db *Database) Rename(oldSid, newSid string) error {
+	return db.redis.Rename(oldSid, newSid)
+}
+
+// FlushDatabase is an optional extension of sessions.Database for callers
+// that want to defer writes until the end of the request instead of
+// round-tripping to the backing store on every mutation. iris's session
+// manager does not call this automatically today, so it's only useful when
+// Config.Buffered is set to true and the application (e.g. a custom
+// middleware running after the handler chain) invokes Flush itself.
+type FlushDatabase interface {
+	Flush(sid string, lifetime sessions.LifeTime) error
+}
+
+var _ FlushDatabase = (*Database)(nil)
+
+// Flush writes any buffered Set/Delete/Clear calls made for sid back to
+// redis in a single SET+EXPIRE and drops sid from the in-memory buffer. It's
+// a no-op when Config.Buffered is false (writes already happened eagerly)
+// or when HashMode is on (each HSET already carries its own EXPIRE).
+func (db *Database) Flush(sid string, lifetime sessions.LifeTime) error {
+	if !db.redis.Config.Buffered || db.redis.Config.HashMode {
+		return nil
+	}
+
+	db.mu.Lock()
+	store, ok := db.buffered[sid]
+	if ok {
+		delete(db.buffered, sid)
+	}
+	db.mu.Unlock()
+
+	if !ok || !store.Dirty() {
+		return nil
+	}
+
+	seconds := int64(lifetime.DurationUntilExpiration().Seconds())
+	valueBytes, err := store.Serialize(db.redis.Config.Serializer)
+	if err != nil {
+		return err
+	}
+	if err = db.redis.Set(sid, valueBytes, seconds); err != nil {
+		return err
+	}
+
+	store.clearDirty()
+	return nil
+}
+
+// FlushMiddleware returns an iris middleware that flushes a session's
+// buffered writes back to redis once the rest of the handler chain has run.
+// iris's session manager has no end-of-request hook of its own to call Flush
+// automatically, so when Config.Buffered is true, register this after the
+// session manager's own handler on any route (or globally) that touches
+// sessions against this Database:
+//
+//	app.Use(sess.Handler())
+//	app.Use(db.FlushMiddleware(sess))
+//
+// It's a no-op, aside from the Sessions.Start lookup, when Config.Buffered
+// is false or Config.HashMode is true, same as Flush itself.
+func (db *Database) FlushMiddleware(sess *sessions.Sessions) context.Handler {
+	return func(ctx context.Context) {
+		ctx.Next()
+
+		session := sess.Start(ctx)
+		if err := db.Flush(session.ID(), session.Lifetime); err != nil {
+			golog.Error(err)
+		}
+	}
+}
+
 // Release destroys the session, it clears and removes the session entry,
 // session manager will create a new session ID on the next request after this call.
 func (db *Database) Release(sid string) {
-	// clear all $sid-$key.
-	db.Clear(sid)
-	// and remove the $sid.
+	// drop any buffered, not-yet-flushed store for sid directly instead of
+	// going through Clear, which would call bufferedStoreFor and re-insert a
+	// fresh, empty entry for a sid that's never coming back.
+	db.mu.Lock()
+	delete(db.buffered, sid)
+	db.mu.Unlock()
+
+	// remove the $sid and all its $sid-$key data (blob or hash).
 	db.redis.Delete(sid)
 }
 
@@ -0,0 +1,39 @@
+package redis
+
+import (
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/kataras/iris/sessions"
+	"github.com/ybtq/iris-redis/service"
+)
+
+func newTestDatabase(t *testing.T, cfg service.Config) *Database {
+	t.Helper()
+
+	mr := miniredis.RunT(t)
+	cfg.Addr = mr.Addr()
+	return New(cfg)
+}
+
+// TestAcquireHashModeDoesNotSeedPlaceholder guards against a WRONGTYPE error:
+// Acquire used to always SET a string placeholder for a brand-new sid, even
+// in HashMode, which left the very first hSet for that sid failing against a
+// key already holding a string.
+func TestAcquireHashModeDoesNotSeedPlaceholder(t *testing.T) {
+	db := newTestDatabase(t, service.Config{HashMode: true})
+	if db == nil {
+		t.Fatal("New returned nil")
+	}
+
+	sid := "sid1"
+	db.Acquire(sid, time.Minute)
+
+	lifetime := sessions.LifeTime{Time: time.Now().Add(time.Minute)}
+	db.Set(sid, lifetime, "name", "kataras", false)
+
+	if got := db.Get(sid, "name"); got != "kataras" {
+		t.Fatalf("Get(name) = %v, want kataras", got)
+	}
+}
@@ -0,0 +1,66 @@
+package service
+
+import "github.com/go-redis/redis/v8"
+
+// ClientMode selects the deployment topology the Service connects to.
+type ClientMode uint8
+
+const (
+	// Single connects to a single redis instance, this is the default.
+	Single ClientMode = iota
+	// Cluster connects to a Redis Cluster deployment, Config.Addrs is the
+	// list of cluster seed nodes.
+	Cluster
+	// Sentinel connects to a Sentinel-managed high-availability deployment,
+	// Config.Addrs is the list of sentinel addresses and Config.MasterName
+	// is required.
+	Sentinel
+)
+
+// Connect prepares the redis client based on the Config, this method
+// should be called only once. The concrete client (single, cluster or
+// sentinel-backed) is picked based on Config.ClientMode, but all of them
+// satisfy redis.UniversalClient so the rest of the Service is unaware of it.
+func (s *Service) Connect() {
+	c := s.Config
+	if c.Timeout <= 0 {
+		c.Timeout = DefaultRedisTimeout
+	}
+
+	switch c.ClientMode {
+	case Cluster:
+		s.client = redis.NewClusterClient(&redis.ClusterOptions{
+			Addrs:          c.Addrs,
+			Password:       c.Password,
+			RouteRandomly:  c.RouteRandomly,
+			RouteByLatency: c.RouteByLatency,
+			DialTimeout:    c.Timeout,
+			IdleTimeout:    c.Timeout,
+		})
+	case Sentinel:
+		s.client = redis.NewFailoverClient(&redis.FailoverOptions{
+			SentinelAddrs: c.Addrs,
+			MasterName:    c.MasterName,
+			Password:      c.Password,
+			DB:            c.Database,
+			DialTimeout:   c.Timeout,
+			IdleTimeout:   c.Timeout,
+		})
+	default:
+		if c.Network == "" {
+			c.Network = DefaultRedisNetwork
+		}
+		if c.Addr == "" {
+			c.Addr = DefaultRedisAddr
+		}
+		s.client = redis.NewClient(&redis.Options{
+			Network:     c.Network,
+			Addr:        c.Addr,
+			Password:    c.Password,
+			DB:          c.Database,
+			DialTimeout: c.Timeout,
+			IdleTimeout: c.Timeout,
+		})
+	}
+	s.Connected = true
+}
@@ -0,0 +1,44 @@
+package service
+
+import (
+	"testing"
+
+	"github.com/go-redis/redis/v8"
+)
+
+func TestConnectBuildsClientForMode(t *testing.T) {
+	tests := []struct {
+		name        string
+		cfg         Config
+		wantCluster bool
+	}{
+		{
+			name: "single",
+			cfg:  Config{ClientMode: Single, Addr: DefaultRedisAddr},
+		},
+		{
+			name:        "cluster",
+			cfg:         Config{ClientMode: Cluster, Addrs: []string{"127.0.0.1:7000"}},
+			wantCluster: true,
+		},
+		{
+			name: "sentinel",
+			cfg:  Config{ClientMode: Sentinel, Addrs: []string{"127.0.0.1:26379"}, MasterName: "mymaster"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := New(tt.cfg)
+			s.Connect()
+
+			_, isCluster := s.client.(*redis.ClusterClient)
+			if isCluster != tt.wantCluster {
+				t.Fatalf("client = %T, wantCluster = %v", s.client, tt.wantCluster)
+			}
+			if s.client == nil {
+				t.Fatal("Connect should always set a client")
+			}
+		})
+	}
+}
@@ -0,0 +1,84 @@
+package service
+
+import "time"
+
+const (
+	// DefaultRedisNetwork the redis network option, "tcp".
+	DefaultRedisNetwork = "tcp"
+	// DefaultRedisAddr the redis address option, "127.0.0.1:6379".
+	DefaultRedisAddr = "127.0.0.1:6379"
+	// DefaultRedisTimeout the redis dial/idle timeout option, defaults to 30 seconds.
+	DefaultRedisTimeout = time.Duration(30) * time.Second
+)
+
+// Config the redis configuration used inside the sessions database.
+type Config struct {
+	// Network protocol. Defaults to "tcp".
+	Network string
+	// Addr of the redis server. Defaults to "127.0.0.1:6379".
+	Addr string
+	// Password redis password. Defaults to "" (no password).
+	Password string
+	// Database the redis database to select after connecting. Defaults to 0.
+	Database int
+	// Timeout for dialing and idle connections. Defaults to 30 seconds.
+	Timeout time.Duration
+
+	// KeyPrefix is prepended to every session id before it reaches redis and
+	// stripped back off of any key returned to the caller (e.g. by
+	// Service.Keys). Use it to namespace sessions when multiple applications
+	// share the same redis instance, e.g. "iris_sess:". Defaults to "".
+	KeyPrefix string
+
+	// ClientMode selects between a Single instance (the default), a Redis
+	// Cluster or a Sentinel-managed deployment.
+	ClientMode ClientMode
+	// Addrs is the list of seed addresses used in Cluster and Sentinel modes,
+	// ignored in Single mode where Addr is used instead. In Sentinel mode
+	// these are the sentinel addresses, not the master's.
+	Addrs []string
+	// MasterName is the sentinel master name, required when ClientMode is Sentinel.
+	MasterName string
+	// RouteRandomly routes readonly commands to a random cluster replica.
+	// Only used when ClientMode is Cluster.
+	RouteRandomly bool
+	// RouteByLatency routes readonly commands to the cluster replica with the
+	// lowest latency. Only used when ClientMode is Cluster.
+	RouteByLatency bool
+
+	// HashMode, when true, stores a session as a single Redis hash (one field per
+	// session key) instead of a single serialized blob. This makes single-key
+	// Set/Get/Delete operations O(1) instead of round-tripping the whole session.
+	// Defaults to false so that sessions already stored as blobs keep working.
+	HashMode bool
+
+	// Serializer encodes/decodes a session's values: the whole session map in
+	// blob mode (HashMode: false), or each field's value on its own in HashMode.
+	// Defaults to GobSerializer, JSONSerializer and MsgpackSerializer are also
+	// available for interoperability with non-Go readers of the session data.
+	Serializer Serializer
+
+	// Buffered, when true, defers Set/Delete/Clear writes in memory instead of
+	// round-tripping to redis on every call, until the caller explicitly
+	// invokes Database.Flush (iris itself does not call it automatically, so
+	// the application, e.g. a custom middleware running after the handler
+	// chain, must do so at the end of each request). Defaults to false, same
+	// as every other flag on this Config, so a Config{} built without going
+	// through DefaultConfig still gets the safe, eager-write behavior this
+	// package always had before buffering existed.
+	Buffered bool
+}
+
+// DefaultConfig returns the default configuration for the redis service.
+func DefaultConfig() Config {
+	return Config{
+		Network:    DefaultRedisNetwork,
+		Addr:       DefaultRedisAddr,
+		Password:   "",
+		Database:   0,
+		Timeout:    DefaultRedisTimeout,
+		HashMode:   false,
+		Serializer: GobSerializer{},
+		Buffered:   false,
+	}
+}
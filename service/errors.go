@@ -0,0 +1,12 @@
+package service
+
+import "errors"
+
+// ErrRedisClosed is returned by CloseConnection when the service's client was
+// never created, i.e. Connect was never called.
+var ErrRedisClosed = errors.New("redis: connection is closed")
+
+// ErrNoKeyPrefix is returned by Keys when Config.KeyPrefix is empty, since a
+// SCAN with an empty prefix (MATCH "*") would enumerate every key in the
+// redis instance, not just sessions, when it's shared with other data.
+var ErrNoKeyPrefix = errors.New("redis: Config.KeyPrefix must be set to enumerate sessions with Keys")
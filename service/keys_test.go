@@ -0,0 +1,51 @@
+package service
+
+import (
+	"testing"
+
+	"github.com/alicebob/miniredis/v2"
+)
+
+func newTestService(t *testing.T, cfg Config) *Service {
+	t.Helper()
+
+	mr := miniredis.RunT(t)
+	cfg.Addr = mr.Addr()
+	s := New(cfg)
+	s.Connect()
+	return s
+}
+
+func TestKeysRequiresKeyPrefix(t *testing.T) {
+	s := newTestService(t, Config{KeyPrefix: ""})
+
+	if _, err := s.Keys(); err != ErrNoKeyPrefix {
+		t.Fatalf("err = %v, want ErrNoKeyPrefix", err)
+	}
+}
+
+func TestKeysStripsPrefix(t *testing.T) {
+	s := newTestService(t, Config{KeyPrefix: "iris_sess:"})
+
+	if err := s.Set("sid1", []byte("a"), 0); err != nil {
+		t.Fatalf("Set: %v", err)
+	}
+	if err := s.Set("sid2", []byte("b"), 0); err != nil {
+		t.Fatalf("Set: %v", err)
+	}
+
+	sids, err := s.Keys()
+	if err != nil {
+		t.Fatalf("Keys: %v", err)
+	}
+
+	want := map[string]bool{"sid1": true, "sid2": true}
+	if len(sids) != len(want) {
+		t.Fatalf("Keys() = %v, want keys for %v", sids, want)
+	}
+	for _, sid := range sids {
+		if !want[sid] {
+			t.Errorf("unexpected sid %q in Keys() result", sid)
+		}
+	}
+}
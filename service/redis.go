@@ -0,0 +1,266 @@
+package service
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+)
+
+// Service is the redis back-end connection bridge, it holds the redis client
+// and the configuration it was built from.
+type Service struct {
+	Connected bool
+	Config    *Config
+
+	client redis.UniversalClient
+}
+
+// New returns a new redis service filled by the fields of the passed config.
+// If no config is passed then the default one is used instead. A Serializer
+// left nil (e.g. a caller building a Config{} literal directly instead of
+// starting from DefaultConfig) falls back to GobSerializer, the same default
+// DefaultConfig uses, since every blob-mode and HashMode read/write goes
+// through it and a nil Serializer would otherwise panic on first use.
+func New(cfg ...Config) *Service {
+	c := DefaultConfig()
+	if len(cfg) > 0 {
+		c = cfg[0]
+	}
+	if c.Serializer == nil {
+		c.Serializer = GobSerializer{}
+	}
+
+	return &Service{Config: &c}
+}
+
+// PingPong sends a PING command to the redis server and reports whether it
+// replied with PONG.
+func (s *Service) PingPong() (bool, error) {
+	msg, err := s.client.Ping(context.Background()).Result()
+	if err != nil {
+		return false, err
+	}
+	return msg == "PONG", nil
+}
+
+// CloseConnection terminates the underlying redis connection.
+func (s *Service) CloseConnection() error {
+	if s.client == nil {
+		return ErrRedisClosed
+	}
+	return s.client.Close()
+}
+
+// key namespaces a session id with Config.KeyPrefix, so that multiple
+// applications can share a single redis instance without colliding.
+func (s *Service) key(sid string) string {
+	return s.Config.KeyPrefix + sid
+}
+
+// Set sets a key-value to the redis store, with an optional TTL (in seconds,
+// zero or negative means no expiration).
+func (s *Service) Set(key string, value interface{}, secondsLifetime int64) error {
+	var ttl time.Duration
+	if secondsLifetime > 0 {
+		ttl = time.Duration(secondsLifetime) * time.Second
+	}
+	return s.client.Set(context.Background(), s.key(key), value, ttl).Err()
+}
+
+// Get returns a value based on its key, it returns the raw []byte as an
+// interface{}, as stored by Set.
+func (s *Service) Get(key string) (interface{}, error) {
+	b, err := s.client.Get(context.Background(), s.key(key)).Bytes()
+	if err != nil {
+		return nil, err
+	}
+	return b, nil
+}
+
+// TTL returns the seconds remaining for a key to expire, whether it has an
+// expiration at all, and whether the key was found.
+func (s *Service) TTL(key string) (seconds int64, hasExpiration bool, found bool) {
+	d, err := s.client.TTL(context.Background(), s.key(key)).Result()
+	if err != nil {
+		return -2, false, false
+	}
+	if d == -2 {
+		// key does not exist.
+		return -2, false, false
+	}
+	if d == -1 {
+		// key exists but has no expiration.
+		return -1, false, true
+	}
+	return int64(d.Seconds()), true, true
+}
+
+// UpdateTTL updates the expiration of an existing key.
+func (s *Service) UpdateTTL(key string, newSecondsLifeTime int64) error {
+	return s.client.Expire(context.Background(), s.key(key), time.Duration(newSecondsLifeTime)*time.Second).Err()
+}
+
+// Delete removes a key from the redis store.
+func (s *Service) Delete(key string) error {
+	return s.client.Del(context.Background(), s.key(key)).Err()
+}
+
+// Exist reports whether a key exists in the redis store.
+func (s *Service) Exist(key string) bool {
+	n, err := s.client.Exists(context.Background(), s.key(key)).Result()
+	if err != nil {
+		return false
+	}
+	return n > 0
+}
+
+// HSet sets a single field of the hash stored at key.
+func (s *Service) HSet(key, field string, value interface{}) error {
+	return s.client.HSet(context.Background(), s.key(key), field, value).Err()
+}
+
+// HGet returns a single field of the hash stored at key.
+func (s *Service) HGet(key, field string) (interface{}, error) {
+	b, err := s.client.HGet(context.Background(), s.key(key), field).Bytes()
+	if err != nil {
+		return nil, err
+	}
+	return b, nil
+}
+
+// HDel removes a single field of the hash stored at key, it reports whether
+// the field existed and was removed.
+func (s *Service) HDel(key, field string) (bool, error) {
+	n, err := s.client.HDel(context.Background(), s.key(key), field).Result()
+	if err != nil {
+		return false, err
+	}
+	return n > 0, nil
+}
+
+// HLen returns the number of fields in the hash stored at key.
+func (s *Service) HLen(key string) (int64, error) {
+	return s.client.HLen(context.Background(), s.key(key)).Result()
+}
+
+// HScan scans the fields and values of the hash stored at key, starting at
+// cursor, it returns the next cursor to continue from (zero once exhausted).
+func (s *Service) HScan(key string, cursor uint64, count int64) (fieldsAndValues []string, nextCursor uint64, err error) {
+	return s.client.HScan(context.Background(), s.key(key), cursor, "", count).Result()
+}
+
+// Rename renames oldKey to newKey, preserving oldKey's remaining TTL. It
+// does not use the RENAME command: in Cluster mode oldKey and newKey are not
+// guaranteed to live on the same hash slot, which would make RENAME fail with
+// a CROSSSLOT error. Instead it reads oldKey's value (or, in HashMode, all of
+// its hash fields), writes it under newKey, re-applies the TTL, then deletes
+// oldKey, which works the same regardless of topology.
+func (s *Service) Rename(oldKey, newKey string) error {
+	ctx := context.Background()
+	from, to := s.key(oldKey), s.key(newKey)
+
+	ttl, err := s.client.PTTL(ctx, from).Result()
+	if err != nil {
+		return err
+	}
+
+	if s.Config.HashMode {
+		fields, err := s.client.HGetAll(ctx, from).Result()
+		if err != nil {
+			return err
+		}
+		if len(fields) > 0 {
+			if err := s.client.HSet(ctx, to, fields).Err(); err != nil {
+				return err
+			}
+		}
+	} else {
+		value, err := s.client.Get(ctx, from).Bytes()
+		if err != nil && err != redis.Nil {
+			return err
+		}
+		if err == nil {
+			if err := s.client.Set(ctx, to, value, 0).Err(); err != nil {
+				return err
+			}
+		}
+	}
+
+	if ttl > 0 {
+		if err := s.client.PExpire(ctx, to, ttl).Err(); err != nil {
+			return err
+		}
+	}
+
+	return s.client.Del(ctx, from).Err()
+}
+
+// Keys scans all session keys under Config.KeyPrefix and returns their sids
+// with the prefix stripped, it uses SCAN rather than the blocking KEYS command
+// so it is safe to call against a live, shared redis instance. In Cluster
+// mode a single SCAN only ever covers the slots owned by one shard, so it
+// scans every master node individually via ForEachMaster instead.
+//
+// Config.KeyPrefix must be non-empty: matching on "*" would enumerate every
+// key in the redis instance, not just sessions, for an application that
+// shares it with other data. Keys returns ErrNoKeyPrefix instead.
+func (s *Service) Keys() ([]string, error) {
+	if s.Config.KeyPrefix == "" {
+		return nil, ErrNoKeyPrefix
+	}
+
+	ctx := context.Background()
+
+	if cluster, ok := s.client.(*redis.ClusterClient); ok {
+		var (
+			mu   sync.Mutex
+			sids []string
+		)
+		err := cluster.ForEachMaster(ctx, func(ctx context.Context, shard *redis.Client) error {
+			shardSids, err := s.scanKeys(ctx, shard)
+			if err != nil {
+				return err
+			}
+
+			mu.Lock()
+			sids = append(sids, shardSids...)
+			mu.Unlock()
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+		return sids, nil
+	}
+
+	return s.scanKeys(ctx, s.client)
+}
+
+// scanKeys scans a single node (or the whole keyspace, outside Cluster mode)
+// for keys under Config.KeyPrefix and returns their sids with the prefix
+// stripped.
+func (s *Service) scanKeys(ctx context.Context, client redis.UniversalClient) ([]string, error) {
+	match := s.Config.KeyPrefix + "*"
+
+	var (
+		cursor uint64
+		sids   []string
+	)
+	for {
+		keys, next, err := client.Scan(ctx, cursor, match, 0).Result()
+		if err != nil {
+			return nil, err
+		}
+		for _, k := range keys {
+			sids = append(sids, strings.TrimPrefix(k, s.Config.KeyPrefix))
+		}
+
+		cursor = next
+		if cursor == 0 {
+			return sids, nil
+		}
+	}
+}
@@ -0,0 +1,55 @@
+package service
+
+import "testing"
+
+func TestRenamePreservesTTLAndValue(t *testing.T) {
+	s := newTestService(t, Config{})
+
+	if err := s.Set("old", []byte("hello"), 100); err != nil {
+		t.Fatalf("Set: %v", err)
+	}
+
+	if err := s.Rename("old", "new"); err != nil {
+		t.Fatalf("Rename: %v", err)
+	}
+
+	if s.Exist("old") {
+		t.Error("old key should no longer exist after Rename")
+	}
+
+	value, err := s.Get("new")
+	if err != nil {
+		t.Fatalf("Get(new): %v", err)
+	}
+	if string(value.([]byte)) != "hello" {
+		t.Errorf("Get(new) = %q, want %q", value, "hello")
+	}
+
+	seconds, hasExpiration, found := s.TTL("new")
+	if !found || !hasExpiration {
+		t.Fatalf("TTL(new) = %d, %v, %v, want a found key with an expiration", seconds, hasExpiration, found)
+	}
+	if seconds <= 0 || seconds > 100 {
+		t.Errorf("TTL(new) = %d, want a value in (0, 100]", seconds)
+	}
+}
+
+func TestRenameHashMode(t *testing.T) {
+	s := newTestService(t, Config{HashMode: true})
+
+	if err := s.HSet("old", "name", "kataras"); err != nil {
+		t.Fatalf("HSet: %v", err)
+	}
+
+	if err := s.Rename("old", "new"); err != nil {
+		t.Fatalf("Rename: %v", err)
+	}
+
+	value, err := s.HGet("new", "name")
+	if err != nil {
+		t.Fatalf("HGet(new): %v", err)
+	}
+	if string(value.([]byte)) != "kataras" {
+		t.Errorf("HGet(new, name) = %q, want %q", value, "kataras")
+	}
+}
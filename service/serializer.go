@@ -0,0 +1,105 @@
+package service
+
+import (
+	"bytes"
+	"encoding/gob"
+	"encoding/json"
+	"fmt"
+
+	"github.com/vmihailenco/msgpack/v5"
+)
+
+// Serializer encodes and decodes a session's values to and from the byte
+// payload stored in Redis. Plug a different implementation via
+// Config.Serializer to change the wire format without touching the Database.
+type Serializer interface {
+	Marshal(map[interface{}]interface{}) ([]byte, error)
+	Unmarshal([]byte, *map[interface{}]interface{}) error
+}
+
+// hashValueKey is the single map key used to round-trip one value through a
+// Serializer in HashMode, where each Redis hash field holds one encoded
+// value rather than a whole session map.
+const hashValueKey = "v"
+
+// MarshalValue encodes a single HashMode field value using the configured
+// Serializer, by wrapping it in a single-entry map so every Serializer
+// implementation (including JSONSerializer's string-key requirement) can be
+// reused as-is.
+func MarshalValue(s Serializer, value interface{}) ([]byte, error) {
+	return s.Marshal(map[interface{}]interface{}{hashValueKey: value})
+}
+
+// UnmarshalValue is the counterpart of MarshalValue.
+func UnmarshalValue(s Serializer, data []byte) (interface{}, error) {
+	values := make(map[interface{}]interface{})
+	if err := s.Unmarshal(data, &values); err != nil {
+		return nil, err
+	}
+	return values[hashValueKey], nil
+}
+
+// GobSerializer is the default Serializer, it uses encoding/gob, every
+// concrete type stored in a session must be registered with gob.Register.
+type GobSerializer struct{}
+
+// Marshal implements the Serializer interface.
+func (GobSerializer) Marshal(values map[interface{}]interface{}) ([]byte, error) {
+	buf := new(bytes.Buffer)
+	if err := gob.NewEncoder(buf).Encode(values); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// Unmarshal implements the Serializer interface.
+func (GobSerializer) Unmarshal(data []byte, values *map[interface{}]interface{}) error {
+	return gob.NewDecoder(bytes.NewBuffer(data)).Decode(values)
+}
+
+// JSONSerializer stores sessions as JSON. JSON objects only support string
+// keys, so every session key must be a string or Marshal returns an error.
+type JSONSerializer struct{}
+
+// Marshal implements the Serializer interface.
+// It returns an error if any key of "values" is not a string.
+func (JSONSerializer) Marshal(values map[interface{}]interface{}) ([]byte, error) {
+	strValues := make(map[string]interface{}, len(values))
+	for k, v := range values {
+		key, ok := k.(string)
+		if !ok {
+			return nil, fmt.Errorf("service: JSONSerializer requires string session keys, got %T", k)
+		}
+		strValues[key] = v
+	}
+	return json.Marshal(strValues)
+}
+
+// Unmarshal implements the Serializer interface.
+func (JSONSerializer) Unmarshal(data []byte, values *map[interface{}]interface{}) error {
+	var strValues map[string]interface{}
+	if err := json.Unmarshal(data, &strValues); err != nil {
+		return err
+	}
+
+	result := make(map[interface{}]interface{}, len(strValues))
+	for k, v := range strValues {
+		result[k] = v
+	}
+	*values = result
+	return nil
+}
+
+// MsgpackSerializer stores sessions as msgpack. Unlike JSONSerializer it keeps
+// arbitrary key types and produces a smaller payload than GobSerializer.
+type MsgpackSerializer struct{}
+
+// Marshal implements the Serializer interface.
+func (MsgpackSerializer) Marshal(values map[interface{}]interface{}) ([]byte, error) {
+	return msgpack.Marshal(values)
+}
+
+// Unmarshal implements the Serializer interface.
+func (MsgpackSerializer) Unmarshal(data []byte, values *map[interface{}]interface{}) error {
+	return msgpack.Unmarshal(data, values)
+}
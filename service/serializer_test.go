@@ -0,0 +1,59 @@
+package service
+
+import "testing"
+
+func TestGobSerializerRoundTrip(t *testing.T) {
+	testSerializerRoundTrip(t, GobSerializer{})
+}
+
+func TestJSONSerializerRoundTrip(t *testing.T) {
+	testSerializerRoundTrip(t, JSONSerializer{})
+}
+
+func TestJSONSerializerRejectsNonStringKeys(t *testing.T) {
+	_, err := JSONSerializer{}.Marshal(map[interface{}]interface{}{1: "v"})
+	if err == nil {
+		t.Fatal("expected an error for a non-string key, got nil")
+	}
+}
+
+func TestMsgpackSerializerRoundTrip(t *testing.T) {
+	testSerializerRoundTrip(t, MsgpackSerializer{})
+}
+
+func testSerializerRoundTrip(t *testing.T, s Serializer) {
+	t.Helper()
+
+	values := map[interface{}]interface{}{"name": "kataras", "age": 30}
+	data, err := s.Marshal(values)
+	if err != nil {
+		t.Fatalf("Marshal: %v", err)
+	}
+
+	var got map[interface{}]interface{}
+	if err := s.Unmarshal(data, &got); err != nil {
+		t.Fatalf("Unmarshal: %v", err)
+	}
+
+	if got["name"] != "kataras" {
+		t.Errorf("name = %v, want kataras", got["name"])
+	}
+}
+
+func TestMarshalUnmarshalValue(t *testing.T) {
+	s := GobSerializer{}
+
+	data, err := MarshalValue(s, "a value")
+	if err != nil {
+		t.Fatalf("MarshalValue: %v", err)
+	}
+
+	got, err := UnmarshalValue(s, data)
+	if err != nil {
+		t.Fatalf("UnmarshalValue: %v", err)
+	}
+
+	if got != "a value" {
+		t.Errorf("got %v, want %q", got, "a value")
+	}
+}
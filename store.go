@@ -1,14 +1,19 @@
 package redis
 
 import (
-	"bytes"
-	"encoding/gob"
 	"sync"
+
+	"github.com/ybtq/iris-redis/service"
 )
 
 type Store struct {
 	lock   sync.RWMutex
 	values map[interface{}]interface{}
+
+	// dirty and changed track the write-through cache's pending mutations,
+	// so Database.Flush can skip writing back a store nothing touched.
+	dirty   bool
+	changed map[interface{}]struct{}
 }
 
 func NewStore() *Store {
@@ -22,6 +27,7 @@ func (rs *Store) Set(key, value interface{}) error {
 	rs.lock.Lock()
 	defer rs.lock.Unlock()
 	rs.values[key] = value
+	rs.touch(key)
 	return nil
 }
 
@@ -40,30 +46,80 @@ func (rs *Store) Delete(key interface{}) error {
 	rs.lock.Lock()
 	defer rs.lock.Unlock()
 	delete(rs.values, key)
+	rs.touch(key)
 	return nil
 }
 
+// DeleteExisting deletes key and reports whether it was present beforehand.
+func (rs *Store) DeleteExisting(key interface{}) (existed bool) {
+	rs.lock.Lock()
+	defer rs.lock.Unlock()
+	_, existed = rs.values[key]
+	if existed {
+		delete(rs.values, key)
+		rs.touch(key)
+	}
+	return
+}
+
 // Flush clear all values
 func (rs *Store) Flush() error {
 	rs.lock.Lock()
 	defer rs.lock.Unlock()
 	rs.values = make(map[interface{}]interface{})
+	rs.dirty = true
+	rs.changed = nil
 	return nil
 }
 
-// Serialize using gob
-func (rs *Store) Serialize() ([]byte, error) {
-	buf := new(bytes.Buffer)
-	enc := gob.NewEncoder(buf)
-	err := enc.Encode(rs.values)
-	if err == nil {
-		return buf.Bytes(), nil
+// touch marks the store dirty and records that key changed. Callers must
+// already hold rs.lock.
+func (rs *Store) touch(key interface{}) {
+	rs.dirty = true
+	if rs.changed == nil {
+		rs.changed = make(map[interface{}]struct{})
+	}
+	rs.changed[key] = struct{}{}
+}
+
+// Dirty reports whether the store has changes that haven't been flushed yet.
+func (rs *Store) Dirty() bool {
+	rs.lock.RLock()
+	defer rs.lock.RUnlock()
+	return rs.dirty
+}
+
+// Changed returns the keys that changed since the store was last flushed.
+func (rs *Store) Changed() []interface{} {
+	rs.lock.RLock()
+	defer rs.lock.RUnlock()
+	keys := make([]interface{}, 0, len(rs.changed))
+	for k := range rs.changed {
+		keys = append(keys, k)
 	}
-	return nil, err
+	return keys
+}
+
+// clearDirty resets the dirty flag and changed-keys set, called after a
+// successful Database.Flush.
+func (rs *Store) clearDirty() {
+	rs.lock.Lock()
+	defer rs.lock.Unlock()
+	rs.dirty = false
+	rs.changed = nil
 }
 
-// Deserialize back to map[interface{}]interface{}
-func (rs *Store) Deserialize(d []byte) error {
-	dec := gob.NewDecoder(bytes.NewBuffer(d))
-	return dec.Decode(&rs.values)
+// Serialize encodes the store's values using the given serializer
+// (service.Config.Serializer, GobSerializer by default).
+func (rs *Store) Serialize(s service.Serializer) ([]byte, error) {
+	rs.lock.RLock()
+	defer rs.lock.RUnlock()
+	return s.Marshal(rs.values)
+}
+
+// Deserialize decodes back into the store's values using the given serializer.
+func (rs *Store) Deserialize(d []byte, s service.Serializer) error {
+	rs.lock.Lock()
+	defer rs.lock.Unlock()
+	return s.Unmarshal(d, &rs.values)
 }
@@ -0,0 +1,68 @@
+package redis
+
+import "testing"
+
+func TestStoreDirtyTracking(t *testing.T) {
+	s := NewStore()
+
+	if s.Dirty() {
+		t.Fatal("a new store should not be dirty")
+	}
+
+	s.Set("name", "kataras")
+	if !s.Dirty() {
+		t.Fatal("Set should mark the store dirty")
+	}
+
+	changed := s.Changed()
+	if len(changed) != 1 || changed[0] != "name" {
+		t.Fatalf("Changed() = %v, want [name]", changed)
+	}
+
+	s.clearDirty()
+	if s.Dirty() {
+		t.Fatal("clearDirty should reset the dirty flag")
+	}
+	if len(s.Changed()) != 0 {
+		t.Fatal("clearDirty should reset the changed-keys set")
+	}
+}
+
+func TestStoreDeleteExisting(t *testing.T) {
+	s := NewStore()
+	s.Set("name", "kataras")
+	s.clearDirty()
+
+	if existed := s.DeleteExisting("missing"); existed {
+		t.Fatal("DeleteExisting should report false for a key that was never set")
+	}
+	if s.Dirty() {
+		t.Fatal("deleting a missing key should not mark the store dirty")
+	}
+
+	if existed := s.DeleteExisting("name"); !existed {
+		t.Fatal("DeleteExisting should report true for a key that was set")
+	}
+	if !s.Dirty() {
+		t.Fatal("deleting an existing key should mark the store dirty")
+	}
+	if s.Get("name") != nil {
+		t.Fatal("the key should be gone after DeleteExisting")
+	}
+}
+
+func TestStoreFlush(t *testing.T) {
+	s := NewStore()
+	s.Set("name", "kataras")
+	s.clearDirty()
+
+	if err := s.Flush(); err != nil {
+		t.Fatalf("Flush: %v", err)
+	}
+	if !s.Dirty() {
+		t.Fatal("Flush should mark the store dirty")
+	}
+	if s.Get("name") != nil {
+		t.Fatal("Flush should clear all values")
+	}
+}